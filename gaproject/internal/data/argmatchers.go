@@ -0,0 +1,26 @@
+package data
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// AnyTime is a sqlmock argument matcher that accepts any time.Time value,
+// for use with WithArgs() calls where the exact timestamp is non-deterministic.
+type AnyTime struct{}
+
+// Match implements sqlmock.Argument.
+func (AnyTime) Match(v driver.Value) bool {
+	_, ok := v.(time.Time)
+	return ok
+}
+
+// AnyBytes is a sqlmock argument matcher that accepts any []byte value, for
+// use with WithArgs() calls over hashed passwords and other derived byte slices.
+type AnyBytes struct{}
+
+// Match implements sqlmock.Argument.
+func (AnyBytes) Match(v driver.Value) bool {
+	_, ok := v.([]byte)
+	return ok
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect selects how MovieModel encodes/decodes the genres column, since the
+// production Postgres driver stores it as a native text[] (via pq.Array)
+// while the SQLite harness used by NewTestDB has no array type and stores a
+// comma-joined string instead.
+type Dialect int
+
+const (
+	DialectPostgres Dialect = iota
+	DialectSQLite
+)
+
+func (m MovieModel) encodeGenres(genres []string) interface{} {
+	if m.Dialect == DialectSQLite {
+		return strings.Join(genres, ",")
+	}
+	return pq.Array(genres)
+}
+
+// scanGenresDest returns the value to pass to Scan() for the genres column,
+// plus a finish func that must be called afterwards to populate *genres.
+func (m MovieModel) scanGenresDest(genres *[]string) (dest interface{}, finish func()) {
+	if m.Dialect == DialectSQLite {
+		var raw string
+		return &raw, func() {
+			if raw == "" {
+				*genres = nil
+				return
+			}
+			*genres = strings.Split(raw, ",")
+		}
+	}
+
+	return pq.Array(genres), func() {}
+}
+
+// isDuplicateKeyError reports whether err is a unique-constraint violation
+// from the underlying driver, so callers can translate it into one of the
+// package's own Err* sentinels instead of leaking a driver-specific type.
+func isDuplicateKeyError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Name() == "unique_violation"
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+
+	return false
+}
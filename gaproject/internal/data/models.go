@@ -0,0 +1,28 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrRecordNotFound = errors.New("record not found")
+	ErrEditConflict   = errors.New("edit conflict")
+	ErrDuplicateEmail = errors.New("duplicate email")
+	ErrDuplicateMovie = errors.New("duplicate title and year")
+)
+
+// Models wraps all of the application's data layer models, so that a single
+// value can be passed around the application and threaded through handlers.
+type Models struct {
+	Movies   MovieModel
+	UserInfo UserInfoModel
+}
+
+// NewModels returns a Models value backed by the given database connection pool.
+func NewModels(db *sql.DB) Models {
+	return Models{
+		Movies:   MovieModel{DB: db},
+		UserInfo: UserInfoModel{DB: db},
+	}
+}
@@ -0,0 +1,166 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// defaultMovieCacheTTL is how long a movie is kept in the cache after a
+	// miss populates it; cache hits do not extend it.
+	defaultMovieCacheTTL = 5 * time.Minute
+	// defaultMovieNotFoundTTL is how long a "not found" result is negatively
+	// cached, to absorb repeated lookups of a missing ID without stampeding
+	// the database.
+	defaultMovieNotFoundTTL = 30 * time.Second
+	// notFoundSentinel is the value stored for a negatively-cached lookup.
+	notFoundSentinel = "\x00not-found"
+	// redisTimeout bounds every Redis round-trip the cache makes, matching
+	// the context.WithTimeout(context.Background(), 3*time.Second) idiom the
+	// rest of this package uses for database calls.
+	redisTimeout = 3 * time.Second
+)
+
+// CachedMovieModel decorates a MoviesRepo with read-through Redis caching of
+// Get, invalidating the cache entry on Update and Delete.
+type CachedMovieModel struct {
+	Repo        MoviesRepo
+	Redis       *redis.Client
+	TTL         time.Duration
+	NotFoundTTL time.Duration
+}
+
+// NewCachedMovieModel returns a CachedMovieModel wrapping repo with the
+// package's default TTLs.
+func NewCachedMovieModel(repo MoviesRepo, client *redis.Client) CachedMovieModel {
+	return CachedMovieModel{
+		Repo:        repo,
+		Redis:       client,
+		TTL:         defaultMovieCacheTTL,
+		NotFoundTTL: defaultMovieNotFoundTTL,
+	}
+}
+
+func movieCacheKey(id int64) string {
+	return fmt.Sprintf("movies:id:%d", id)
+}
+
+// movieCacheEntry mirrors Movie for the cache payload. Movie.CreatedAt is
+// tagged json:"-" for the API response, so Movie itself can't round-trip
+// through JSON without losing it; this type gives CreatedAt a real tag.
+type movieCacheEntry struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Title     string    `json:"title"`
+	Year      int32     `json:"year"`
+	Runtime   int32     `json:"runtime"`
+	Genres    []string  `json:"genres"`
+	Version   int32     `json:"version"`
+}
+
+func newMovieCacheEntry(movie *Movie) movieCacheEntry {
+	return movieCacheEntry{
+		ID:        movie.ID,
+		CreatedAt: movie.CreatedAt,
+		Title:     movie.Title,
+		Year:      movie.Year,
+		Runtime:   movie.Runtime,
+		Genres:    movie.Genres,
+		Version:   movie.Version,
+	}
+}
+
+func (e movieCacheEntry) toMovie() *Movie {
+	return &Movie{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		Title:     e.Title,
+		Year:      e.Year,
+		Runtime:   e.Runtime,
+		Genres:    e.Genres,
+		Version:   e.Version,
+	}
+}
+
+func (c CachedMovieModel) Get(id int64) (*Movie, error) {
+	key := movieCacheKey(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	cached, err := c.Redis.Get(ctx, key).Result()
+	cancel()
+
+	switch {
+	case err == nil:
+		if cached == notFoundSentinel {
+			return nil, ErrRecordNotFound
+		}
+
+		var entry movieCacheEntry
+		if err := json.Unmarshal([]byte(cached), &entry); err != nil {
+			return nil, err
+		}
+		return entry.toMovie(), nil
+
+	case errors.Is(err, redis.Nil):
+		// Cache miss, fall through to the underlying repo.
+
+	default:
+		// Redis being unavailable shouldn't take reads down with it: log
+		// and fall through to the underlying repo as if it were a miss.
+		log.Printf("movies cache: get %s: %v", key, err)
+	}
+
+	movie, err := c.Repo.Get(id)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			setCtx, setCancel := context.WithTimeout(context.Background(), redisTimeout)
+			c.Redis.Set(setCtx, key, notFoundSentinel, c.NotFoundTTL)
+			setCancel()
+		}
+		return nil, err
+	}
+
+	if encoded, err := json.Marshal(newMovieCacheEntry(movie)); err == nil {
+		setCtx, setCancel := context.WithTimeout(context.Background(), redisTimeout)
+		c.Redis.Set(setCtx, key, encoded, c.TTL)
+		setCancel()
+	}
+
+	return movie, nil
+}
+
+func (c CachedMovieModel) Insert(movie *Movie) error {
+	return c.Repo.Insert(movie)
+}
+
+func (c CachedMovieModel) Update(movie *Movie) error {
+	if err := c.Repo.Update(movie); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	c.Redis.Del(ctx, movieCacheKey(movie.ID))
+
+	return nil
+}
+
+func (c CachedMovieModel) Delete(id int64) error {
+	if err := c.Repo.Delete(id); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	c.Redis.Del(ctx, movieCacheKey(id))
+
+	return nil
+}
+
+var _ MoviesRepo = CachedMovieModel{}
@@ -0,0 +1,168 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedMovieModelForTest(t *testing.T) (CachedMovieModel, sqlmock.Sqlmock, *miniredis.Miniredis) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	cached := NewCachedMovieModel(MovieModel{DB: db}, client)
+
+	return cached, mock, mr
+}
+
+func TestCachedMovieModelGetCacheMissPopulatesCache(t *testing.T) {
+	cached, mock, _ := newCachedMovieModelForTest(t)
+
+	movie := &Movie{ID: 1, CreatedAt: time.Now().UTC().Round(0), Title: "Mock Movie", Year: 2022, Runtime: 120, Genres: []string{"Action"}, Version: 1}
+
+	mock.ExpectQuery("^SELECT (.+) FROM movies WHERE id = \\$1").
+		WithArgs(movie.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "title", "year", "runtime", "genres", "version"}).
+			AddRow(movie.ID, movie.CreatedAt, movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.Version))
+
+	got, err := cached.Get(movie.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, movie, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Second call must be served from cache: no new query expectation was
+	// registered, so sqlmock would make Get return an error if the DB were hit
+	// again.
+	got, err = cached.Get(movie.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, movie, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedMovieModelGetCacheHitSkipsDB(t *testing.T) {
+	cached, mock, _ := newCachedMovieModelForTest(t)
+
+	movie := &Movie{ID: 2, CreatedAt: time.Now().UTC().Round(0), Title: "Pre-warmed", Year: 2020, Runtime: 90, Genres: []string{"Drama"}, Version: 1}
+
+	payload, err := json.Marshal(newMovieCacheEntry(movie))
+	if err != nil {
+		t.Fatalf("failed to marshal movie fixture: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := cached.Redis.Set(ctx, movieCacheKey(movie.ID), payload, time.Minute).Err(); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	got, err := cached.Get(movie.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, movie, got)
+
+	// No query expectations were set on mock at all, so any database query
+	// would have made Get return an error; the NoError above confirms the
+	// database was never touched.
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedMovieModelUpdateInvalidatesCache(t *testing.T) {
+	cached, mock, _ := newCachedMovieModelForTest(t)
+
+	movie := &Movie{ID: 3, CreatedAt: time.Now().UTC().Round(0), Title: "Stale", Year: 2019, Runtime: 100, Genres: []string{"Comedy"}, Version: 1}
+
+	mock.ExpectQuery("^SELECT (.+) FROM movies WHERE id = \\$1").
+		WithArgs(movie.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "title", "year", "runtime", "genres", "version"}).
+			AddRow(movie.ID, movie.CreatedAt, movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.Version))
+
+	_, err := cached.Get(movie.ID)
+	assert.NoError(t, err)
+
+	updated := *movie
+	updated.Title = "Updated"
+	mock.ExpectQuery("^UPDATE movies").
+		WithArgs(updated.Title, updated.Year, updated.Runtime, pq.Array(updated.Genres), updated.ID, updated.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+	err = cached.Update(&updated)
+	assert.NoError(t, err)
+
+	mock.ExpectQuery("^SELECT (.+) FROM movies WHERE id = \\$1").
+		WithArgs(movie.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "title", "year", "runtime", "genres", "version"}).
+			AddRow(updated.ID, updated.CreatedAt, updated.Title, updated.Year, updated.Runtime, pq.Array(updated.Genres), 2))
+
+	got, err := cached.Get(movie.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated", got.Title)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedMovieModelNegativeCacheExpires(t *testing.T) {
+	cached, mock, mr := newCachedMovieModelForTest(t)
+	cached.NotFoundTTL = 10 * time.Second
+
+	mock.ExpectQuery("^SELECT (.+) FROM movies WHERE id = \\$1").
+		WithArgs(int64(99)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := cached.Get(99)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Still within the negative-cache TTL: no second query expected.
+	_, err = cached.Get(99)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mr.FastForward(cached.NotFoundTTL + time.Second)
+
+	mock.ExpectQuery("^SELECT (.+) FROM movies WHERE id = \\$1").
+		WithArgs(int64(99)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = cached.Get(99)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCachedMovieModelGetFallsThroughOnRedisError(t *testing.T) {
+	cached, mock, mr := newCachedMovieModelForTest(t)
+
+	// Simulate Redis being unreachable: the client's commands now fail with
+	// a connection error rather than redis.Nil.
+	mr.Close()
+
+	movie := &Movie{ID: 4, CreatedAt: time.Now().UTC().Round(0), Title: "Degraded", Year: 2021, Runtime: 95, Genres: []string{"Horror"}, Version: 1}
+
+	mock.ExpectQuery("^SELECT (.+) FROM movies WHERE id = \\$1").
+		WithArgs(movie.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "title", "year", "runtime", "genres", "version"}).
+			AddRow(movie.ID, movie.CreatedAt, movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.Version))
+
+	got, err := cached.Get(movie.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, movie, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
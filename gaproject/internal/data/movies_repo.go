@@ -0,0 +1,13 @@
+package data
+
+// MoviesRepo is the interface implemented by MovieModel and its decorators
+// (such as CachedMovieModel), so callers can depend on the behavior rather
+// than a concrete storage backend.
+type MoviesRepo interface {
+	Insert(movie *Movie) error
+	Get(id int64) (*Movie, error)
+	Update(movie *Movie) error
+	Delete(id int64) error
+}
+
+var _ MoviesRepo = MovieModel{}
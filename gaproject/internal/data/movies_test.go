@@ -1,6 +1,7 @@
 package data
 
 import (
+	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
@@ -10,17 +11,6 @@ import (
 
 // UNIT TEST
 func TestMovieInsert(t *testing.T) {
-	// Create a new mock database.
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
-
-	// Create a MovieModel instance with the mock database.
-	m := MovieModel{DB: db}
-
-	// Define a mock movie.
 	mockMovie := &Movie{
 		Title:   "Test Movie 1",
 		Year:    2024,
@@ -28,23 +18,63 @@ func TestMovieInsert(t *testing.T) {
 		Genres:  []string{"Action", "Adventure"},
 	}
 
-	// Set up expectations for the mock database query.
-	mock.ExpectQuery("^INSERT INTO movies (.+) RETURNING id, created_at, version").
-		WithArgs(mockMovie.Title, mockMovie.Year, mockMovie.Runtime, pq.Array(mockMovie.Genres)).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "version"}).
-			AddRow(1, time.Now(), 1))
+	tests := []struct {
+		name        string
+		buildStub   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "success",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^INSERT INTO movies (.+) RETURNING id, created_at, version").
+					WithArgs(mockMovie.Title, mockMovie.Year, mockMovie.Runtime, pq.Array(mockMovie.Genres)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "version"}).
+						AddRow(1, time.Now(), 1))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "duplicate title and year",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^INSERT INTO movies (.+) RETURNING id, created_at, version").
+					WithArgs(mockMovie.Title, mockMovie.Year, mockMovie.Runtime, pq.Array(mockMovie.Genres)).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "movies_title_year_key"})
+			},
+			expectedErr: ErrDuplicateMovie,
+		},
+		{
+			name: "row scan failure",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^INSERT INTO movies (.+) RETURNING id, created_at, version").
+					WithArgs(mockMovie.Title, mockMovie.Year, mockMovie.Runtime, pq.Array(mockMovie.Genres)).
+					WillReturnError(sql.ErrConnDone)
+			},
+			expectedErr: sql.ErrConnDone,
+		},
+	}
 
-	// Call the Insert method with the mock movie.
-	err = m.Insert(mockMovie)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
 
-	// Check for errors.
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
-	}
+			m := MovieModel{DB: db}
+			tt.buildStub(mock)
 
-	// Verify that all expectations were met.
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("there were unfulfilled expectations: %s", err)
+			movie := *mockMovie
+			err = m.Insert(&movie)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
 	}
 }
 
@@ -93,18 +123,7 @@ func TestMovieGet(t *testing.T) {
 }
 
 func TestMovieUpdate(t *testing.T) {
-	// Create a new mock database.
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
-	}
-	defer db.Close()
-
-	// Create a MovieModel instance with the mock database.
-	m := MovieModel{DB: db}
-
-	// Define mock movie.
-	movie := &Movie{
+	baseMovie := &Movie{
 		ID:      1,
 		Title:   "Test Movie",
 		Year:    2023,
@@ -113,23 +132,62 @@ func TestMovieUpdate(t *testing.T) {
 		Version: 1,
 	}
 
-	// Define mock rows for UPDATE query.
-	rows := sqlmock.NewRows([]string{"version"}).AddRow(2)
+	tests := []struct {
+		name        string
+		buildStub   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "success",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^UPDATE movies").
+					WithArgs(baseMovie.Title, baseMovie.Year, baseMovie.Runtime, pq.Array(baseMovie.Genres), baseMovie.ID, baseMovie.Version).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "edit conflict on version mismatch",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^UPDATE movies").
+					WithArgs(baseMovie.Title, baseMovie.Year, baseMovie.Runtime, pq.Array(baseMovie.Genres), baseMovie.ID, baseMovie.Version).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}))
+			},
+			expectedErr: ErrEditConflict,
+		},
+		{
+			name: "duplicate title and year",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^UPDATE movies").
+					WithArgs(baseMovie.Title, baseMovie.Year, baseMovie.Runtime, pq.Array(baseMovie.Genres), baseMovie.ID, baseMovie.Version).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "movies_title_year_key"})
+			},
+			expectedErr: ErrDuplicateMovie,
+		},
+	}
 
-	// Set up expectations for the mock database query.
-	mock.ExpectQuery("^UPDATE movies").
-		WithArgs(movie.Title, movie.Year, movie.Runtime, pq.Array(movie.Genres), movie.ID, movie.Version).
-		WillReturnRows(rows)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
 
-	// Call the Update method with the mock movie.
-	err = m.Update(movie)
+			m := MovieModel{DB: db}
+			tt.buildStub(mock)
 
-	// Check for errors.
-	assert.NoError(t, err)
+			movie := *baseMovie
+			err = m.Update(&movie)
 
-	// Verify that all expectations were met.
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("there were unfulfilled expectations: %s", err)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
 	}
 }
 
@@ -166,13 +224,9 @@ func TestMovieDelete(t *testing.T) {
 
 // INTEGRATION TEST
 func TestMovieInsertIntegration(t *testing.T) {
-	db, err := setupDatabase()
-	if err != nil {
-		t.Fatalf("Failed to set up database: %v", err)
-	}
-	defer db.Close()
+	db := NewTestDB(t)
 
-	m := MovieModel{DB: db}
+	m := MovieModel{DB: db, Dialect: DialectSQLite}
 
 	movie := &Movie{
 		Title:   "Test Movie 1",
@@ -181,18 +235,14 @@ func TestMovieInsertIntegration(t *testing.T) {
 		Genres:  []string{"Action", "Adventure"},
 	}
 
-	err = m.Insert(movie)
+	err := m.Insert(movie)
 	assert.NoError(t, err, "Failed to insert movie")
 }
 
 func TestMovieGetIntegration(t *testing.T) {
-	db, err := setupDatabase()
-	if err != nil {
-		t.Fatalf("Failed to set up database: %v", err)
-	}
-	defer db.Close()
+	db := NewTestDB(t)
 
-	m := MovieModel{DB: db}
+	m := MovieModel{DB: db, Dialect: DialectSQLite}
 
 	movie := &Movie{
 		Title:   "Test Movie 1",
@@ -201,7 +251,7 @@ func TestMovieGetIntegration(t *testing.T) {
 		Genres:  []string{"Action", "Adventure"},
 	}
 
-	err = m.Insert(movie)
+	err := m.Insert(movie)
 	assert.NoError(t, err, "Failed to insert movie")
 
 	insertedMovie, err := m.Get(movie.ID)
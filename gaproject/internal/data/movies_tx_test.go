@@ -0,0 +1,81 @@
+package data
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovieRecordView(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m := MovieModel{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE movies SET views = views \\+ 1 WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO movie_viewers").
+		WithArgs(int64(1), int64(42), AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = m.RecordView(42, 1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMovieRecordViewRollsBackOnAuditInsertFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m := MovieModel{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE movies SET views = views \\+ 1 WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO movie_viewers").
+		WithArgs(int64(1), int64(42), AnyTime{}).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = m.RecordView(42, 1)
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMovieRecordViewSurfacesCommitFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m := MovieModel{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE movies SET views = views \\+ 1 WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO movie_viewers").
+		WithArgs(int64(1), int64(42), AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(sql.ErrConnDone)
+
+	err = m.RecordView(42, 1)
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,76 @@
+package data
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptCost is the work factor passed to bcrypt.GenerateFromPassword. Bumping
+// it rehashes future passwords at the new cost; existing hashes keep validating
+// since bcrypt embeds its own cost in the hash.
+const bcryptCost = 12
+
+// ErrPasswordNotSet is returned when a User with no password hash is persisted
+// or matched. Only the presence of a hash is checked: a User loaded from the
+// database carries its stored hash and is accepted without SetPassword.
+var ErrPasswordNotSet = errors.New("password not set")
+
+// ErrPasswordEmpty is returned by SetPassword when passed an empty plaintext.
+var ErrPasswordEmpty = errors.New("password: plaintext password must not be empty")
+
+// Set hashes plaintextPassword with bcrypt and stores both the plaintext
+// (retained only for validation use in tests/handlers) and the resulting hash.
+func (p *password) Set(plaintextPassword string) error {
+	if plaintextPassword == "" {
+		return ErrPasswordEmpty
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(plaintextPassword), bcryptCost)
+	if err != nil {
+		return err
+	}
+
+	p.plaintext = &plaintextPassword
+	p.hash = hash
+
+	return nil
+}
+
+// Matches reports whether plaintextPassword corresponds to the stored hash.
+// On a successful match against a hash stored at an older bcryptCost, it
+// transparently rehashes the password at the current cost so the caller can
+// persist the upgrade (e.g. via UserInfoModel.Update).
+func (p *password) Matches(plaintextPassword string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword(p.hash, []byte(plaintextPassword))
+	if err != nil {
+		switch {
+		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	if cost, costErr := bcrypt.Cost(p.hash); costErr == nil && cost != bcryptCost {
+		_ = p.Set(plaintextPassword)
+	}
+
+	return true, nil
+}
+
+// SetPassword hashes plaintext and stores it on the user's Password field.
+func (u *User) SetPassword(plaintext string) error {
+	return u.Password.Set(plaintext)
+}
+
+// Matches reports whether plaintext corresponds to the user's stored password
+// hash. It returns ErrPasswordNotSet if the user has no hash, i.e. neither
+// SetPassword was called nor a hash was loaded from the database.
+func (u *User) Matches(plaintext string) (bool, error) {
+	if u.Password.hash == nil {
+		return false, ErrPasswordNotSet
+	}
+
+	return u.Password.Matches(plaintext)
+}
@@ -0,0 +1,67 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserSetPasswordAndMatches(t *testing.T) {
+	user := &User{}
+
+	err := user.SetPassword("correct-horse-battery-staple")
+	assert.NoError(t, err)
+
+	ok, err := user.Matches("correct-horse-battery-staple")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestUserMatchesWrongPassword(t *testing.T) {
+	user := &User{}
+
+	err := user.SetPassword("correct-horse-battery-staple")
+	assert.NoError(t, err)
+
+	ok, err := user.Matches("wrong-password")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestUserSetPasswordEmpty(t *testing.T) {
+	user := &User{}
+
+	err := user.SetPassword("")
+	assert.Error(t, err)
+}
+
+func TestUserMatchesBeforeSetPassword(t *testing.T) {
+	user := &User{}
+
+	_, err := user.Matches("anything")
+	assert.True(t, errors.Is(err, ErrPasswordNotSet))
+}
+
+func TestUserMatchesRehashesOnCostChange(t *testing.T) {
+	user := &User{}
+
+	oldHash, err := bcrypt.GenerateFromPassword([]byte("correct-horse-battery-staple"), bcryptCost-1)
+	assert.NoError(t, err)
+	user.Password.hash = oldHash
+
+	ok, err := user.Matches("correct-horse-battery-staple")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NotEqual(t, oldHash, user.Password.hash, "Matches should rehash a hash stored at an old cost")
+
+	newCost, err := bcrypt.Cost(user.Password.hash)
+	assert.NoError(t, err)
+	assert.Equal(t, bcryptCost, newCost)
+
+	ok, err = user.Matches("correct-horse-battery-staple")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
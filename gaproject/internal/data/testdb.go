@@ -0,0 +1,63 @@
+package data
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// NewTestDB opens a fresh in-memory SQLite database, applies the embedded
+// SQLite-flavoured migrations, and registers a t.Cleanup that closes it. It
+// replaces the previous setupDatabase helper, which pointed every
+// "integration" test at one developer's local Postgres instance.
+func NewTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", "file::memory:?_foreign_keys=on&cache=shared")
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite database: %v", err)
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+// migrateSQLite applies every *.sql file under migrations/sqlite in
+// lexical order, so files are named with a numeric prefix (0001_, 0002_, ...).
+func migrateSQLite(db *sql.DB) error {
+	entries, err := fs.ReadDir(sqliteMigrations, "migrations/sqlite")
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		contents, err := sqliteMigrations.ReadFile("migrations/sqlite/" + entry.Name())
+		if err != nil {
+			return err
+		}
+
+		if _, err := db.Exec(string(contents)); err != nil {
+			return fmt.Errorf("applying migration %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
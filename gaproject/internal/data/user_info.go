@@ -0,0 +1,261 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type password struct {
+	plaintext *string
+	hash      []byte
+}
+
+type User struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"fname"`
+	Surname   string    `json:"lname"`
+	Email     string    `json:"email"`
+	Password  password  `json:"-"`
+	Role      string    `json:"role"`
+	Activated bool      `json:"activated"`
+	Version   int32     `json:"-"`
+}
+
+// Clock abstracts time.Now so tests can inject a fixed point in time instead
+// of relying on wildcard argument matching.
+type Clock func() time.Time
+
+type UserInfoModel struct {
+	DB    *sql.DB
+	Clock Clock
+}
+
+func (m UserInfoModel) now() time.Time {
+	if m.Clock != nil {
+		return m.Clock()
+	}
+	return time.Now()
+}
+
+func (m UserInfoModel) Insert(user *User) error {
+	if user.Password.hash == nil {
+		return ErrPasswordNotSet
+	}
+
+	query := `
+		INSERT INTO user_info (fname, lname, email, password_hash, user_role, activated)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, version`
+
+	args := []interface{}{user.Name, user.Surname, user.Email, user.Password.hash, user.Role, user.Activated}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.ID, &user.CreatedAt, &user.Version)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m UserInfoModel) Get(id int64) (*User, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, created_at, updated_at, fname, lname, email, password_hash, user_role, activated, version
+		FROM user_info
+		WHERE id = $1`
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Name,
+		&user.Surname,
+		&user.Email,
+		&user.Password.hash,
+		&user.Role,
+		&user.Activated,
+		&user.Version,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func (m UserInfoModel) GetByEmail(email string) (*User, error) {
+	query := `
+		SELECT id, created_at, updated_at, fname, lname, email, password_hash, user_role, activated, version
+		FROM user_info
+		WHERE email = $1`
+
+	var user User
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Name,
+		&user.Surname,
+		&user.Email,
+		&user.Password.hash,
+		&user.Role,
+		&user.Activated,
+		&user.Version,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &user, nil
+}
+
+func (m UserInfoModel) Update(user *User) error {
+	if user.Password.hash == nil {
+		return ErrPasswordNotSet
+	}
+
+	query := `
+		UPDATE user_info
+		SET fname = $1, lname = $2, email = $3, password_hash = $4, activated = $5, updated_at = $6, version = version + 1
+		WHERE id = $7 AND version = $8
+		RETURNING version`
+
+	args := []interface{}{
+		user.Name,
+		user.Surname,
+		user.Email,
+		user.Password.hash,
+		user.Activated,
+		m.now(),
+		user.ID,
+		user.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case isDuplicateKeyError(err):
+			return ErrDuplicateEmail
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m UserInfoModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM user_info WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// WithTx runs fn inside a database transaction, committing if fn returns nil
+// and rolling back otherwise. A panic inside fn is rolled back and re-panicked.
+func (m UserInfoModel) WithTx(fn func(*sql.Tx) error) (err error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	err = fn(tx)
+	return err
+}
+
+// ChangeEmail atomically updates a user's email address and appends a row to
+// the email_change_log audit table recording the transition.
+func (m UserInfoModel) ChangeEmail(userID int64, newEmail string) error {
+	return m.WithTx(func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+
+		var oldEmail string
+		err := tx.QueryRowContext(ctx, `SELECT email FROM user_info WHERE id = $1`, userID).Scan(&oldEmail)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE user_info SET email = $1 WHERE id = $2`, newEmail, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO email_change_log (user_id, old_email, new_email, changed_at) VALUES ($1, $2, $3, $4)`,
+			userID, oldEmail, newEmail, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
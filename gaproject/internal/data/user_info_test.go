@@ -3,140 +3,252 @@ package data
 import (
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
-	"log"
 	"testing"
 	"time"
 )
 
-func setupDatabase() (*sql.DB, error) {
-	connStr := "user=postgres password=2005 dbname=gaproject sslmode=disable"
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		log.Fatal("Failed to open a DB connection: ", err)
-		return nil, err
-	}
-
-	return db, nil
-}
-
 // UNIT TEST
 func TestUserInfoInsert(t *testing.T) {
-	// Create a new mock database.
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	newMockUser := func() *User {
+		user := &User{
+			Name:      "Test1",
+			Surname:   "Test1",
+			Email:     "Test1@example.com",
+			Role:      "user",
+			Activated: true,
+		}
+		err := user.SetPassword("correct-horse-battery-staple")
+		assert.NoError(t, err)
+		return user
 	}
-	defer db.Close()
-
-	// Create a UserInfoModel instance with the mock database.
-	m := UserInfoModel{DB: db}
 
-	// Define mock user.
-	user := &User{
-		Name:      "Test1",
-		Surname:   "Test1",
-		Email:     "Test1@example.com",
-		Password:  password{hash: []byte("hashedpassword")},
-		Role:      "user",
-		Activated: true,
+	tests := []struct {
+		name        string
+		buildStub   func(sqlmock.Sqlmock)
+		user        *User
+		expectedErr error
+	}{
+		{
+			name: "success",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mockUser := newMockUser()
+				mock.ExpectQuery("^INSERT INTO user_info").
+					WithArgs(mockUser.Name, mockUser.Surname, mockUser.Email, AnyBytes{}, mockUser.Role, mockUser.Activated).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "version"}).
+						AddRow(1, time.Now(), 1))
+			},
+			user:        newMockUser(),
+			expectedErr: nil,
+		},
+		{
+			name:        "password not set",
+			buildStub:   func(mock sqlmock.Sqlmock) {},
+			user:        &User{Name: "Test1", Surname: "Test1", Email: "Test1@example.com", Role: "user", Activated: true},
+			expectedErr: ErrPasswordNotSet,
+		},
+		{
+			name: "duplicate email",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mockUser := newMockUser()
+				mock.ExpectQuery("^INSERT INTO user_info").
+					WithArgs(mockUser.Name, mockUser.Surname, mockUser.Email, AnyBytes{}, mockUser.Role, mockUser.Activated).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "user_info_email_key"})
+			},
+			user:        newMockUser(),
+			expectedErr: ErrDuplicateEmail,
+		},
+		{
+			name: "row scan failure",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mockUser := newMockUser()
+				mock.ExpectQuery("^INSERT INTO user_info").
+					WithArgs(mockUser.Name, mockUser.Surname, mockUser.Email, AnyBytes{}, mockUser.Role, mockUser.Activated).
+					WillReturnError(sql.ErrConnDone)
+			},
+			user:        newMockUser(),
+			expectedErr: sql.ErrConnDone,
+		},
 	}
 
-	// Define mock rows.
-	rows := sqlmock.NewRows([]string{"id", "created_at", "version"}).
-		AddRow(1, time.Now(), 1)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
 
-	// Set up expectations for the mock database query.
-	mock.ExpectQuery("^INSERT INTO user_info").
-		WillReturnRows(rows)
+			m := UserInfoModel{DB: db}
+			tt.buildStub(mock)
 
-	// Call the Insert method with the mock user.
-	err = m.Insert(user)
+			user := *tt.user
+			err = m.Insert(&user)
 
-	// Check for errors.
-	assert.NoError(t, err)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
 
-	// Verify that all expectations were met.
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("there were unfulfilled expectations: %s", err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
 	}
 }
 
 func TestUserInfoGetByEmail(t *testing.T) {
-	// Create a new mock database.
-	db, mock, err := sqlmock.New()
-	if err != nil {
-		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	email := "Test1@example.com"
+
+	tests := []struct {
+		name        string
+		buildStub   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "success",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "fname", "lname", "email", "password_hash", "user_role", "activated", "version"}).
+					AddRow(1, time.Now(), time.Now(), "John", "Doe", "john.doe@example.com", []byte("hashedpassword"), "user", true, 1)
+
+				mock.ExpectQuery("^SELECT id, created_at, updated_at, fname, lname, email, password_hash, user_role, activated, version FROM user_info WHERE email").
+					WillReturnRows(rows)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "no matching row",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^SELECT id, created_at, updated_at, fname, lname, email, password_hash, user_role, activated, version FROM user_info WHERE email").
+					WillReturnError(sql.ErrNoRows)
+			},
+			expectedErr: ErrRecordNotFound,
+		},
 	}
-	defer db.Close()
 
-	// Create a UserInfoModel instance with the mock database.
-	m := UserInfoModel{DB: db}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			m := UserInfoModel{DB: db}
+			tt.buildStub(mock)
+
+			user, err := m.GetByEmail(email)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, user)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
 
-	// Define mock email.
-	email := "Test1@example.com"
+func TestUserInfoUpdate(t *testing.T) {
+	// Fix the clock so the updated_at argument can be asserted exactly
+	// instead of matched with a wildcard.
+	fixedNow := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	baseUser := &User{
+		ID:        1,
+		Name:      "Test",
+		Surname:   "User",
+		Email:     "test@example.com",
+		Activated: true,
+		Version:   1,
+	}
+	err := baseUser.SetPassword("correct-horse-battery-staple")
+	assert.NoError(t, err)
 
-	// Define mock rows.
-	rows := sqlmock.NewRows([]string{"id", "created_at", "updated_at", "fname", "lname", "email", "password_hash", "user_role", "activated", "version"}).
-		AddRow(1, time.Now(), time.Now(), "John", "Doe", "john.doe@example.com", []byte("hashedpassword"), "user", true, 1)
+	tests := []struct {
+		name        string
+		buildStub   func(sqlmock.Sqlmock)
+		expectedErr error
+	}{
+		{
+			name: "success",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^UPDATE user_info").
+					WithArgs(baseUser.Name, baseUser.Surname, baseUser.Email, AnyBytes{}, baseUser.Activated, fixedNow, baseUser.ID, baseUser.Version).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "edit conflict on version mismatch",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^UPDATE user_info").
+					WithArgs(baseUser.Name, baseUser.Surname, baseUser.Email, AnyBytes{}, baseUser.Activated, fixedNow, baseUser.ID, baseUser.Version).
+					WillReturnRows(sqlmock.NewRows([]string{"version"}))
+			},
+			expectedErr: ErrEditConflict,
+		},
+		{
+			name: "duplicate email",
+			buildStub: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("^UPDATE user_info").
+					WithArgs(baseUser.Name, baseUser.Surname, baseUser.Email, AnyBytes{}, baseUser.Activated, fixedNow, baseUser.ID, baseUser.Version).
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "user_info_email_key"})
+			},
+			expectedErr: ErrDuplicateEmail,
+		},
+	}
 
-	// Set up expectations for the mock database query.
-	mock.ExpectQuery("^SELECT id, created_at, updated_at, fname, lname, email, password_hash, user_role, activated, version FROM user_info WHERE email").
-		WillReturnRows(rows)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
 
-	// Call the GetByEmail method with the mock email.
-	user, err := m.GetByEmail(email)
+			m := UserInfoModel{DB: db, Clock: func() time.Time { return fixedNow }}
+			tt.buildStub(mock)
 
-	// Check for errors.
-	assert.NoError(t, err)
-	assert.NotNil(t, user)
+			user := *baseUser
+			err = m.Update(&user)
 
-	// Verify that all expectations were met.
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("there were unfulfilled expectations: %s", err)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
 	}
 }
 
-func TestUserInfoUpdate(t *testing.T) {
-	// Create a new mock database.
+func TestUserInfoUpdatePasswordNotSet(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
 
-	// Create a UserInfoModel instance with the mock database.
 	m := UserInfoModel{DB: db}
 
-	// Define mock user.
 	user := &User{
 		ID:        1,
 		Name:      "Test",
 		Surname:   "User",
 		Email:     "test@example.com",
-		Password:  password{hash: []byte("hashedpassword")},
 		Activated: true,
 		Version:   1,
 	}
 
-	// Define mock rows for UPDATE query.
-	rows := sqlmock.NewRows([]string{"version"}).AddRow(2)
-
-	// Set up expectations for the mock database query.
-	mock.ExpectQuery("^UPDATE user_info").
-		WithArgs(user.Name, user.Surname, user.Email, user.Password.hash, user.Activated, time.Now(), user.ID, user.Version).
-		WillReturnRows(rows)
-
-	// Call the Update method with the mock user.
 	err = m.Update(user)
 
-	// Check for errors.
-	assert.NoError(t, err)
-
-	// Verify that all expectations were met.
-	if err := mock.ExpectationsWereMet(); err != nil {
-		t.Errorf("there were unfulfilled expectations: %s", err)
-	}
+	assert.ErrorIs(t, err, ErrPasswordNotSet)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestUserInfoDelete(t *testing.T) {
@@ -172,26 +284,27 @@ func TestUserInfoDelete(t *testing.T) {
 
 // INTEGRATION TEST
 func TestUserInfoUpdateIntegration(t *testing.T) {
-	db, err := setupDatabase()
-	if err != nil {
-		t.Fatalf("Failed to set up database: %v", err)
-	}
-	defer db.Close()
+	db := NewTestDB(t)
 
 	m := UserInfoModel{DB: db}
 
 	user := &User{
-		ID:        1,
 		Name:      "test1",
 		Surname:   "test1",
 		Email:     "Zhanassetkazy@example.com",
-		Password:  password{hash: []byte("updatedhashedpassword")},
 		Activated: true,
-		Version:   16,
 	}
+	err := user.SetPassword("hashedpassword")
+	assert.NoError(t, err)
 
-	err = m.Update(user)
+	err = m.Insert(user)
+	assert.NoError(t, err, "Failed to insert user")
+
+	user.Surname = "updated"
+	err = user.SetPassword("updatedhashedpassword")
+	assert.NoError(t, err)
 
+	err = m.Update(user)
 	assert.NoError(t, err, "Failed to update user")
 
 	updatedUser, err := m.Get(user.ID)
@@ -205,19 +318,26 @@ func TestUserInfoUpdateIntegration(t *testing.T) {
 }
 
 func TestUserInfoDeleteIntegration(t *testing.T) {
-	db, err := setupDatabase()
-	if err != nil {
-		t.Fatalf("Failed to set up database: %v", err)
-	}
-	defer db.Close()
+	db := NewTestDB(t)
 
 	m := UserInfoModel{DB: db}
 
-	err = m.Delete(int64(3))
+	user := &User{
+		Name:      "test1",
+		Surname:   "test1",
+		Email:     "todelete@example.com",
+		Activated: true,
+	}
+	err := user.SetPassword("hashedpassword")
+	assert.NoError(t, err)
+
+	err = m.Insert(user)
+	assert.NoError(t, err, "Failed to insert user")
 
+	err = m.Delete(user.ID)
 	assert.NoError(t, err, "Failed to delete user")
 
-	deletedUser, err := m.Get(3)
+	deletedUser, err := m.Get(user.ID)
 
 	assert.Error(t, err, "Expected an error as the user should be deleted")
 	assert.Equal(t, ErrRecordNotFound, err)
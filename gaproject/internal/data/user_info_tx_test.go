@@ -0,0 +1,90 @@
+package data
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserInfoChangeEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m := UserInfoModel{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT email FROM user_info WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("old@example.com"))
+	mock.ExpectExec("^UPDATE user_info SET email = \\$1 WHERE id = \\$2").
+		WithArgs("new@example.com", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO email_change_log").
+		WithArgs(int64(1), "old@example.com", "new@example.com", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = m.ChangeEmail(1, "new@example.com")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserInfoChangeEmailRollsBackOnLogInsertFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m := UserInfoModel{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT email FROM user_info WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("old@example.com"))
+	mock.ExpectExec("^UPDATE user_info SET email = \\$1 WHERE id = \\$2").
+		WithArgs("new@example.com", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO email_change_log").
+		WithArgs(int64(1), "old@example.com", "new@example.com", AnyTime{}).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	err = m.ChangeEmail(1, "new@example.com")
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserInfoChangeEmailSurfacesCommitFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	m := UserInfoModel{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^SELECT email FROM user_info WHERE id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("old@example.com"))
+	mock.ExpectExec("^UPDATE user_info SET email = \\$1 WHERE id = \\$2").
+		WithArgs("new@example.com", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("^INSERT INTO email_change_log").
+		WithArgs(int64(1), "old@example.com", "new@example.com", AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(sql.ErrConnDone)
+
+	err = m.ChangeEmail(1, "new@example.com")
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}